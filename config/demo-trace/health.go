@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mohamedasifs123/otel/config/demo-trace/spdkclient"
+)
+
+// defaultMethodStaleAfter is used when a method's own interval is unknown or zero.
+const defaultMethodStaleAfter = 15 * time.Second
+
+// methodHealth is the most recent outcome recorded for one configured scrape method,
+// plus how long that method's own result is allowed to go stale before it's
+// considered unhealthy.
+type methodHealth struct {
+	ok       bool
+	at       time.Time
+	err      error
+	maxStale time.Duration
+}
+
+// scrapeHealth tracks the outcome of the most recent scrape of each configured
+// method so /healthz and /readyz can reflect real poller state instead of just "the
+// process is up". Scraper.Run calls Record once per method per its own ticker, so
+// state is kept per method rather than last-writer-wins: with more than one
+// configured method, a single shared "most recent scrape" would flip healthy/
+// unhealthy based on whichever method happened to tick last. Staleness is likewise
+// judged per method against that method's own interval, not the longest configured
+// one: otherwise a stalled fast method could hide behind a slow one's budget.
+type scrapeHealth struct {
+	mu       sync.RWMutex
+	methods  map[string]methodHealth
+	maxStale map[string]time.Duration
+}
+
+// newScrapeHealth sizes each configured method's staleness budget off its own
+// interval (3x, the same multiplier the single-value version used), so a method
+// isn't judged unhealthy just because it hasn't ticked again yet.
+func newScrapeHealth(methods []spdkclient.MethodConfig) *scrapeHealth {
+	maxStale := make(map[string]time.Duration, len(methods))
+	for _, m := range methods {
+		stale := 3 * m.Interval
+		if stale <= 0 {
+			stale = defaultMethodStaleAfter
+		}
+		maxStale[m.Method] = stale
+	}
+	return &scrapeHealth{
+		methods:  make(map[string]methodHealth),
+		maxStale: maxStale,
+	}
+}
+
+// staleAfter returns how long method's result may age before it's stale.
+func (h *scrapeHealth) staleAfter(method string) time.Duration {
+	if d, ok := h.maxStale[method]; ok {
+		return d
+	}
+	return defaultMethodStaleAfter
+}
+
+// Record is meant to be used as a spdkclient.Scraper onResult callback.
+func (h *scrapeHealth) Record(method string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.methods[method] = methodHealth{ok: err == nil, at: time.Now(), err: err, maxStale: h.staleAfter(method)}
+}
+
+// Healthy reports whether the process is alive enough to serve traffic: it has
+// nothing to do with whether the latest scrapes succeeded, only whether every
+// method that has ever reported is still reporting within its own interval.
+func (h *scrapeHealth) Healthy() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.methods) == 0 {
+		return true // startup grace period, before the first scrape has run
+	}
+	for _, m := range h.methods {
+		if time.Since(m.at) > m.maxStale {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready reports whether every configured method's most recent scrape succeeded and
+// wasn't stale, i.e. whether it's safe to say this instance's metrics reflect
+// current SPDK state. A configured method that has not reported yet counts as not
+// ready. err joins the recorded errors of the failing methods; a method that is only
+// stale or has not reported yet adds no error of its own.
+func (h *scrapeHealth) Ready() (ok bool, err error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.methods) == 0 {
+		return false, nil
+	}
+	var errs []error
+	ready := true
+	for _, m := range h.methods {
+		if time.Since(m.at) > m.maxStale {
+			ready = false
+			errs = append(errs, m.err)
+			continue
+		}
+		if !m.ok {
+			ready = false
+			errs = append(errs, m.err)
+		}
+	}
+	for method := range h.maxStale {
+		if _, ok := h.methods[method]; !ok {
+			ready = false
+		}
+	}
+	return ready, errors.Join(errs...)
+}
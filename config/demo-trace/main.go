@@ -1,84 +1,129 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
+	"flag"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/mohamedasifs123/otel/config/demo-trace/spdkclient"
 )
 
-type Bdev struct {
-	Name              string `json:"name"`
-	BytesRead         int64  `json:"bytes_read"`
-	NumReadOps        int64  `json:"num_read_ops"`
-	BytesWritten      int64  `json:"bytes_written"`
-	NumWriteOps       int64  `json:"num_write_ops"`
-	ReadLatencyTicks  int64  `json:"read_latency_ticks"`
-	WriteLatencyTicks int64  `json:"write_latency_ticks"`
+// latencyHistogramView configures spdk.bdev.{read,write}.latency as base-2 exponential
+// histograms, so per-bdev latency distributions stay bounded in size regardless of how
+// many distinct bdevs or how wide the latency range is.
+func latencyHistogramView() sdkmetric.View {
+	return sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "spdk.bdev.*.latency"},
+		sdkmetric.Stream{
+			Aggregation: sdkmetric.AggregationBase2ExponentialHistogram{
+				MaxSize:  160,
+				MaxScale: 20,
+			},
+		},
+	)
 }
 
-type SPDKResponse struct {
-	Result struct {
-		Bdevs []Bdev `json:"bdevs"`
-	} `json:"result"`
-}
+const (
+	metricExportInterval  = 10 * time.Second
+	traceBatchTimeout     = 5 * time.Second
+	traceMaxQueueSize     = 2048
+	traceMaxExportBatch   = 512
+	shutdownGracePeriod   = 5 * time.Second
+	resourceDetectTimeout = 5 * time.Second
+)
+
+// defaultServiceName is used when OTEL_SERVICE_NAME is unset.
+const defaultServiceName = "spdk-client"
 
-func initProvider() func() {
-	ctx := context.Background()
+// initProvider wires up the trace, metric and log providers and returns the
+// slog.Logger to use for the rest of the program plus a shutdown func that flushes
+// and closes all three, joining any errors from them rather than dropping one.
+// client is used by the custom SPDK resource detector; it does not issue any scrapes.
+func initProvider(ctx context.Context, client *spdkclient.Client) (*slog.Logger, func() error) {
+	handleErr(validateOTLPProtocol(), "invalid OTEL_EXPORTER_OTLP_PROTOCOL")
 
-	res, err := resource.New(ctx,
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
 
+	// The SPDK detector issues RPCs and SPDK may not be up yet (or may be behind a
+	// firewall that drops rather than refuses), so bound resource.New instead of
+	// letting an unreachable endpoint stall startup, and with it /healthz/readyz,
+	// for however long the transport's dial takes.
+	detectCtx, cancelDetect := context.WithTimeout(ctx, resourceDetectTimeout)
+	defer cancelDetect()
+
+	res, err := resource.New(detectCtx,
 		resource.WithAttributes(
-			semconv.ServiceNameKey.String("spdk-client"),
+			semconv.ServiceNameKey.String(serviceName),
 		),
+		resource.WithHost(),
+		resource.WithOS(),
+		resource.WithProcess(),
+		resource.WithContainer(),
+		resource.WithFromEnv(),
+		resource.WithDetectors(spdkclient.NewResourceDetector(client)),
 	)
 	handleErr(err, "failed to create resource")
 
 	otelAgentAddr := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
 	if otelAgentAddr == "" {
-		otelAgentAddr = "otel-gw-collector:4317"
+		otelAgentAddr = defaultOTLPEndpoint()
 	}
+	otlpHdrs := otlpHeaders()
 
 	// Metric Exporter
-	metricExp, err := otlpmetricgrpc.New(
-		ctx,
-		otlpmetricgrpc.WithInsecure(),
-		otlpmetricgrpc.WithEndpoint(otelAgentAddr),
-	)
+	metricExp, err := newMetricExporter(ctx, otelAgentAddr, otlpHdrs)
 	handleErr(err, "Failed to create the collector metric exporter")
 
+	// Prometheus exporter acts as a second, pull-based reader alongside the periodic
+	// OTLP push above. The Without* options keep SPDK metric names (e.g.
+	// spdk_bdev_bytes_read) stable for dashboards built before this exporter existed.
+	promExp, err := prometheus.New(
+		prometheus.WithoutScopeInfo(),
+		prometheus.WithoutUnits(),
+		prometheus.WithoutCounterSuffixes(),
+	)
+	handleErr(err, "Failed to create the Prometheus exporter")
+
 	meterProvider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExp)),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExp, sdkmetric.WithInterval(metricExportInterval))),
+		sdkmetric.WithReader(promExp),
 		sdkmetric.WithResource(res),
+		sdkmetric.WithView(latencyHistogramView()),
 	)
 	otel.SetMeterProvider(meterProvider)
 
 	// Trace Exporter
-	traceClient := otlptracegrpc.NewClient(
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(otelAgentAddr))
-	traceExp, err := otlptrace.New(ctx, traceClient)
+	traceExp, err := otlptrace.New(ctx, newTraceClient(otelAgentAddr, otlpHdrs))
 	handleErr(err, "Failed to create trace exporter")
 
-	bsp := sdktrace.NewBatchSpanProcessor(traceExp)
+	bsp := sdktrace.NewBatchSpanProcessor(traceExp,
+		sdktrace.WithMaxQueueSize(traceMaxQueueSize),
+		sdktrace.WithBatchTimeout(traceBatchTimeout),
+		sdktrace.WithMaxExportBatchSize(traceMaxExportBatch),
+	)
 	tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithSpanProcessor(bsp),
 		sdktrace.WithResource(res),
@@ -86,73 +131,99 @@ func initProvider() func() {
 	otel.SetTracerProvider(tracerProvider)
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 
-	return func() {
-		cxt, cancel := context.WithTimeout(ctx, time.Second)
+	// Log Exporter
+	logExp, err := newLogExporter(ctx, otelAgentAddr, otlpHdrs)
+	handleErr(err, "Failed to create log exporter")
+
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExp)),
+		sdklog.WithResource(res),
+	)
+
+	// otelslog auto-injects trace_id/span_id from the context passed to the *Context
+	// slog methods, so a latency anomaly in Jaeger can be pivoted straight to the
+	// surrounding SPDK RPC logs.
+	logger := otelslog.NewLogger(serviceName, otelslog.WithLoggerProvider(loggerProvider))
+
+	return logger, func() error {
+		cxt, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
 		defer cancel()
-		if err := traceExp.Shutdown(cxt); err != nil {
-			log.Printf("Failed to shutdown trace exporter: %v", err)
-		}
-		if err := meterProvider.Shutdown(cxt); err != nil {
-			log.Printf("Failed to shutdown metric exporter: %v", err)
-		}
+		return errors.Join(
+			tracerProvider.Shutdown(cxt),
+			meterProvider.Shutdown(cxt),
+			loggerProvider.Shutdown(cxt),
+		)
 	}
 }
 
+// handleErr is only used for setup-time failures the process can't recover from, so
+// it still exits the process; it uses the stdlib logger because it may run before
+// the OTel logger exists.
 func handleErr(err error, message string) {
 	if err != nil {
 		log.Fatalf("%s: %v", message, err)
 	}
 }
 
-func fetchSPDKMetrics(ctx context.Context) []Bdev {
-	url := "http://spdk:9009"
-	reqBody := []byte(`{"id":1, "method": "bdev_get_iostat"}`)
+func main() {
+	configPath := flag.String("config", os.Getenv("SPDK_CONFIG_PATH"), "path to the SPDK client YAML config")
+	rpcAddr := flag.String("spdk-rpc-addr", "", "SPDK JSON-RPC address, e.g. http://spdk:9009 or unix:///var/tmp/spdk.sock (overrides config file and SPDK_RPC_ADDR)")
+	flag.Parse()
 
-	client := http.Client{
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		log.Fatalf("Failed to create request: %v", err)
+	cfg, err := spdkclient.LoadConfig(*configPath)
+	handleErr(err, "Failed to load SPDK client config")
+	if *rpcAddr != "" {
+		cfg.Addr = *rpcAddr
 	}
-	req.SetBasicAuth("spdkuser", "spdkpass")
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
+	client, err := spdkclient.NewClient(cfg)
+	handleErr(err, "Failed to create SPDK client")
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Failed to read response body: %v", err)
-	}
+	logger, shutdown := initProvider(ctx, client)
+	defer func() {
+		if err := shutdown(); err != nil {
+			logger.Error("error shutting down providers", "error", err)
+		}
+	}()
 
-	var response SPDKResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		log.Fatalf("Failed to parse SPDK response: %v", err)
-	}
+	tracer := otel.Tracer("spdk-client")
+	meter := otel.Meter("spdk-client-meter")
 
-	return response.Result.Bdevs
-}
+	bdevPoller, err := spdkclient.NewBdevPoller(client, meter)
+	handleErr(err, "Failed to create bdev poller")
 
-func main() {
-	shutdown := initProvider()
-	defer shutdown()
+	rateGaugeReg, err := bdevPoller.RegisterRateGauges(meter)
+	handleErr(err, "Failed to register bdev rate gauges")
+	defer rateGaugeReg.Unregister()
 
-	tracer := otel.Tracer("spdk-client")
-	meter := otel.Meter("spdk-client-meter")
+	scraper, err := spdkclient.NewScraper(client, cfg, meter, bdevPoller)
+	handleErr(err, "Failed to create SPDK scraper")
+
+	health := newScrapeHealth(cfg.Methods)
+
+	debugAddr := os.Getenv("DEBUG_LISTEN_ADDR")
+	if debugAddr == "" {
+		debugAddr = ":9464"
+	}
+	debugSrv := newDebugServer(debugAddr, promhttp.Handler(), health)
+	debugSrvErr := runDebugServer(ctx, debugSrv)
 
-	// Metrics
+	// Preserve the original cumulative counters and per-scrape span/log line on top
+	// of the poller's latency histograms.
 	bytesRead, _ := meter.Int64Counter("spdk/bdev/bytes_read")
 	numReadOps, _ := meter.Int64Counter("spdk/bdev/read_ops")
 
-	for{
-		ctx, span := tracer.Start(context.Background(), "FetchSPDKMetrics")
-		bdevs := fetchSPDKMetrics(ctx)
+	scraper.SetHandler("bdev_get_iostat", func(ctx context.Context) error {
+		ctx, span := tracer.Start(ctx, "FetchSPDKMetrics")
+		defer span.End()
+
+		bdevs, err := bdevPoller.Poll(ctx)
+		if err != nil {
+			return err
+		}
 
 		for _, bdev := range bdevs {
 			attributes := []attribute.KeyValue{
@@ -161,10 +232,23 @@ func main() {
 			bytesRead.Add(ctx, bdev.BytesRead, metric.WithAttributes(attributes...))
 			numReadOps.Add(ctx, bdev.NumReadOps, metric.WithAttributes(attributes...))
 
-			fmt.Printf("Bdev: %s, BytesRead: %d, NumReadOps: %d\n",
-				bdev.Name, bdev.BytesRead, bdev.NumReadOps)
+			logger.InfoContext(ctx, "scraped bdev",
+				"bdev.name", bdev.Name,
+				"bytes_read", bdev.BytesRead,
+				"num_read_ops", bdev.NumReadOps,
+			)
 		}
-		span.End()
-		time.Sleep(5 * time.Second)
+		return nil
+	})
+
+	scraper.Run(ctx, func(method string, err error) {
+		health.Record(method, err)
+		if err != nil {
+			logger.Error("scrape failed", "method", method, "error", err)
+		}
+	})
+
+	if err := <-debugSrvErr; err != nil {
+		logger.Error("debug server error", "error", err)
 	}
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// otlpProtocol returns the protocol the OTLP exporters should speak, honoring
+// OTEL_EXPORTER_OTLP_PROTOCOL ("grpc" or "http/protobuf") per the OTel SDK spec.
+func otlpProtocol() string {
+	if p := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); p != "" {
+		return p
+	}
+	return "grpc"
+}
+
+// defaultOTLPEndpoint returns the fallback used when OTEL_EXPORTER_OTLP_ENDPOINT is
+// unset, picking the conventional port for whichever protocol otlpProtocol selects
+// (4317 for grpc, 4318 for http/protobuf) so a deployment that only sets
+// OTEL_EXPORTER_OTLP_PROTOCOL doesn't silently end up talking to the wrong port.
+func defaultOTLPEndpoint() string {
+	if otlpProtocol() == "http/protobuf" {
+		return "otel-gw-collector:4318"
+	}
+	return "otel-gw-collector:4317"
+}
+
+// otlpHeaders parses OTEL_EXPORTER_OTLP_HEADERS, a comma-separated list of
+// key=value pairs, into a map suitable for the exporters' WithHeaders option.
+func otlpHeaders() map[string]string {
+	headers := map[string]string{}
+	for _, kv := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+func newMetricExporter(ctx context.Context, endpoint string, headers map[string]string) (sdkmetric.Exporter, error) {
+	if otlpProtocol() == "http/protobuf" {
+		return otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithInsecure(),
+			otlpmetrichttp.WithEndpoint(endpoint),
+			otlpmetrichttp.WithHeaders(headers),
+		)
+	}
+	return otlpmetricgrpc.New(ctx,
+		otlpmetricgrpc.WithInsecure(),
+		otlpmetricgrpc.WithEndpoint(endpoint),
+		otlpmetricgrpc.WithHeaders(headers),
+	)
+}
+
+func newTraceClient(endpoint string, headers map[string]string) otlptrace.Client {
+	if otlpProtocol() == "http/protobuf" {
+		return otlptracehttp.NewClient(
+			otlptracehttp.WithInsecure(),
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithHeaders(headers),
+		)
+	}
+	return otlptracegrpc.NewClient(
+		otlptracegrpc.WithInsecure(),
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithHeaders(headers),
+	)
+}
+
+func newLogExporter(ctx context.Context, endpoint string, headers map[string]string) (sdklog.Exporter, error) {
+	if otlpProtocol() == "http/protobuf" {
+		return otlploghttp.New(ctx,
+			otlploghttp.WithInsecure(),
+			otlploghttp.WithEndpoint(endpoint),
+			otlploghttp.WithHeaders(headers),
+		)
+	}
+	return otlploggrpc.New(ctx,
+		otlploggrpc.WithInsecure(),
+		otlploggrpc.WithEndpoint(endpoint),
+		otlploggrpc.WithHeaders(headers),
+	)
+}
+
+// validateOTLPProtocol fails fast with a clear message rather than a confusing
+// exporter error if OTEL_EXPORTER_OTLP_PROTOCOL is set to something unsupported.
+func validateOTLPProtocol() error {
+	switch p := otlpProtocol(); p {
+	case "grpc", "http/protobuf":
+		return nil
+	default:
+		return fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q, want grpc or http/protobuf", p)
+	}
+}
@@ -0,0 +1,195 @@
+package spdkclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Bdev mirrors one entry of SPDK's bdev_get_iostat result.
+type Bdev struct {
+	Name              string `json:"name"`
+	BytesRead         int64  `json:"bytes_read"`
+	NumReadOps        int64  `json:"num_read_ops"`
+	BytesWritten      int64  `json:"bytes_written"`
+	NumWriteOps       int64  `json:"num_write_ops"`
+	ReadLatencyTicks  int64  `json:"read_latency_ticks"`
+	WriteLatencyTicks int64  `json:"write_latency_ticks"`
+}
+
+// BdevIOStatResult is the result field of a bdev_get_iostat call.
+type BdevIOStatResult struct {
+	TickRate uint64 `json:"tick_rate"`
+	Bdevs    []Bdev `json:"bdevs"`
+}
+
+// ReactorsResult is the result field of a framework_get_reactors call. Its tick_rate
+// is the authoritative conversion factor from SPDK's latency ticks to wall-clock time.
+// Reactors is left as raw messages since only its length (the reactor count) is used
+// today.
+type ReactorsResult struct {
+	TickRate uint64            `json:"tick_rate"`
+	Reactors []json.RawMessage `json:"reactors"`
+}
+
+// GetTickRate fetches SPDK's current tick rate (ticks per second) via framework_get_reactors.
+func (c *Client) GetTickRate(ctx context.Context) (uint64, error) {
+	var res ReactorsResult
+	if err := c.Call(ctx, "framework_get_reactors", nil, &res); err != nil {
+		return 0, fmt.Errorf("spdkclient: framework_get_reactors: %w", err)
+	}
+	return res.TickRate, nil
+}
+
+// BdevPoller polls bdev_get_iostat and turns cumulative counters into per-scrape
+// read/write latency observations, recorded as exponential histograms so latency
+// distributions stay bounded in cardinality regardless of how many bdevs exist. It
+// also keeps the last two scrape snapshots around so observable gauges (see
+// rates.go) can derive IOPS, throughput and queue depth without re-polling SPDK.
+type BdevPoller struct {
+	client *Client
+
+	mu       sync.Mutex
+	prev     map[string]Bdev
+	prevTime time.Time
+	last     map[string]Bdev
+	lastTime time.Time
+	tickRate uint64
+
+	attrsLoaded bool
+	attrs       map[string]bdevAttrs
+
+	readLatency  metric.Float64Histogram
+	writeLatency metric.Float64Histogram
+}
+
+// NewBdevPoller registers the latency histograms on meter and returns a poller
+// ready to be driven by a scrape loop.
+func NewBdevPoller(client *Client, meter metric.Meter) (*BdevPoller, error) {
+	readLatency, err := meter.Float64Histogram("spdk.bdev.read.latency",
+		metric.WithDescription("Bdev read latency since the previous scrape, derived from read_latency_ticks"),
+		metric.WithUnit("ns"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("spdkclient: create read latency histogram: %w", err)
+	}
+	writeLatency, err := meter.Float64Histogram("spdk.bdev.write.latency",
+		metric.WithDescription("Bdev write latency since the previous scrape, derived from write_latency_ticks"),
+		metric.WithUnit("ns"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("spdkclient: create write latency histogram: %w", err)
+	}
+
+	return &BdevPoller{
+		client:       client,
+		attrs:        make(map[string]bdevAttrs),
+		readLatency:  readLatency,
+		writeLatency: writeLatency,
+	}, nil
+}
+
+// Poll scrapes bdev_get_iostat once, records the read/write latency deltas since the
+// previous call, and returns the raw bdev snapshot for callers that also want the
+// cumulative counters (e.g. bytes_read, read_ops).
+func (p *BdevPoller) Poll(ctx context.Context) ([]Bdev, error) {
+	if !p.haveAttrs() {
+		p.loadAttrs(ctx)
+	}
+
+	var res BdevIOStatResult
+	if err := p.client.Call(ctx, "bdev_get_iostat", nil, &res); err != nil {
+		return nil, fmt.Errorf("spdkclient: bdev_get_iostat: %w", err)
+	}
+
+	tickRate := res.TickRate
+	if tickRate == 0 {
+		tickRate, _ = p.client.GetTickRate(ctx)
+	}
+	if tickRate == 0 {
+		tickRate = 1
+	}
+
+	now := time.Now()
+	snapshot := make(map[string]Bdev, len(res.Bdevs))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, b := range res.Bdevs {
+		attrs := metric.WithAttributes(attribute.String("bdev.name", b.Name))
+		if prev, ok := p.last[b.Name]; ok {
+			if dt := b.ReadLatencyTicks - prev.ReadLatencyTicks; dt > 0 {
+				p.readLatency.Record(ctx, ticksToNanos(dt, tickRate), attrs)
+			}
+			if dt := b.WriteLatencyTicks - prev.WriteLatencyTicks; dt > 0 {
+				p.writeLatency.Record(ctx, ticksToNanos(dt, tickRate), attrs)
+			}
+		}
+		snapshot[b.Name] = b
+	}
+
+	p.prev, p.prevTime = p.last, p.lastTime
+	p.last, p.lastTime = snapshot, now
+	p.tickRate = tickRate
+
+	return res.Bdevs, nil
+}
+
+func ticksToNanos(ticks int64, tickRate uint64) float64 {
+	return float64(ticks) / float64(tickRate) * 1e9
+}
+
+// bdevAttrs holds the resource-ish attributes of a bdev that don't change on every
+// scrape, fetched once via bdev_get_bdevs instead of on every bdev_get_iostat poll.
+type bdevAttrs struct {
+	ProductName string
+	Driver      string
+}
+
+// bdevInfo mirrors one entry of SPDK's bdev_get_bdevs result.
+type bdevInfo struct {
+	Name           string                     `json:"name"`
+	ProductName    string                     `json:"product_name"`
+	DriverSpecific map[string]json.RawMessage `json:"driver_specific"`
+}
+
+func (b bdevInfo) driver() string {
+	for name := range b.DriverSpecific {
+		return name
+	}
+	return ""
+}
+
+// haveAttrs reports whether loadAttrs has ever completed successfully.
+func (p *BdevPoller) haveAttrs() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.attrsLoaded
+}
+
+// loadAttrs fetches bdev_get_bdevs and caches product_name/driver per bdev name. A
+// failure here isn't fatal to the scrape it's attempted from: the gauges just fall
+// back to empty attribute values for that scrape, and Poll retries loadAttrs on
+// every subsequent call until it succeeds (SPDK may still be starting up when the
+// first poll runs, sharing that poll's scrape timeout), rather than giving up after
+// one attempt for the life of the process.
+func (p *BdevPoller) loadAttrs(ctx context.Context) {
+	var infos []bdevInfo
+	if err := p.client.Call(ctx, "bdev_get_bdevs", nil, &infos); err != nil {
+		return
+	}
+	attrs := make(map[string]bdevAttrs, len(infos))
+	for _, info := range infos {
+		attrs[info.Name] = bdevAttrs{ProductName: info.ProductName, Driver: info.driver()}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attrs = attrs
+	p.attrsLoaded = true
+}
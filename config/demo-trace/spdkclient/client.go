@@ -0,0 +1,78 @@
+// Package spdkclient talks to an SPDK JSON-RPC endpoint and knows how to turn
+// its responses into OTel metrics.
+package spdkclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// Client issues JSON-RPC 2.0 calls against an SPDK instance's RPC endpoint, over
+// whichever transport cfg.Addr selects (HTTP(S) or a Unix domain socket).
+type Client struct {
+	transport transport
+	nextID    atomic.Int64
+}
+
+// NewClient builds a Client from cfg. The returned Client is safe for concurrent use.
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("spdkclient: addr is required")
+	}
+	t, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{transport: t}, nil
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("spdk rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Call invokes method over JSON-RPC with the given params and decodes the result
+// into out. out may be nil if the caller doesn't need the result.
+func (c *Client) Call(ctx context.Context, method string, params, out any) error {
+	req := rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	}
+
+	resp, err := c.transport.call(ctx, req)
+	if err != nil {
+		return fmt.Errorf("spdkclient: call %s: %w", method, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("spdkclient: decode result for %s: %w", method, err)
+	}
+	return nil
+}
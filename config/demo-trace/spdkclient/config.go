@@ -0,0 +1,124 @@
+package spdkclient
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AuthType identifies how requests to the SPDK JSON-RPC endpoint are authenticated.
+type AuthType string
+
+const (
+	AuthNone   AuthType = "none"
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+)
+
+// AuthConfig configures how the client authenticates against the SPDK JSON-RPC server.
+type AuthConfig struct {
+	Type     AuthType `yaml:"type"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	Token    string   `yaml:"token"`
+}
+
+// MethodConfig describes one JSON-RPC method to poll on its own schedule.
+type MethodConfig struct {
+	Method   string        `yaml:"method"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Retries  int           `yaml:"retries"`
+}
+
+// TLSConfig configures client-certificate TLS for the HTTP(S) transport. It has no
+// effect on the Unix-socket transport.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	CAFile   string `yaml:"ca_file"`
+}
+
+// Config is the top-level configuration for the SPDK client and its pollers.
+type Config struct {
+	// Addr is the JSON-RPC endpoint, e.g. "http://spdk:9009" or "unix:///var/tmp/spdk.sock".
+	Addr    string         `yaml:"addr"`
+	Auth    AuthConfig     `yaml:"auth"`
+	TLS     TLSConfig      `yaml:"tls"`
+	Methods []MethodConfig `yaml:"methods"`
+}
+
+// DefaultConfig returns the configuration the package used before it became configurable:
+// a single bdev_get_iostat poll every 5s against http://spdk:9009.
+func DefaultConfig() *Config {
+	return &Config{
+		Addr: "http://spdk:9009",
+		Methods: []MethodConfig{
+			{Method: "bdev_get_iostat", Interval: 5 * time.Second, Timeout: 2 * time.Second, Retries: 2},
+		},
+	}
+}
+
+// LoadConfig reads a YAML config file at path, then applies environment overrides on top of it.
+// An empty path skips the file and starts from DefaultConfig.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("spdkclient: reading config %q: %w", path, err)
+		}
+		cfg = &Config{}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("spdkclient: parsing config %q: %w", path, err)
+		}
+	}
+
+	applyEnvOverrides(cfg)
+
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = DefaultConfig().Methods
+	}
+	for i := range cfg.Methods {
+		if cfg.Methods[i].Interval == 0 {
+			cfg.Methods[i].Interval = 5 * time.Second
+		}
+		if cfg.Methods[i].Timeout == 0 {
+			cfg.Methods[i].Timeout = 2 * time.Second
+		}
+	}
+
+	return cfg, nil
+}
+
+// applyEnvOverrides lets deployment environments override the RPC endpoint and auth
+// without editing the YAML file, which is the common case for credentials.
+func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("SPDK_RPC_ADDR"); v != "" {
+		cfg.Addr = v
+	}
+	if v := os.Getenv("SPDK_AUTH_TYPE"); v != "" {
+		cfg.Auth.Type = AuthType(v)
+	}
+	if v := os.Getenv("SPDK_AUTH_USERNAME"); v != "" {
+		cfg.Auth.Username = v
+	}
+	if v := os.Getenv("SPDK_AUTH_PASSWORD"); v != "" {
+		cfg.Auth.Password = v
+	}
+	if v := os.Getenv("SPDK_AUTH_TOKEN"); v != "" {
+		cfg.Auth.Token = v
+	}
+	if v := os.Getenv("SPDK_TLS_CERT_FILE"); v != "" {
+		cfg.TLS.CertFile = v
+	}
+	if v := os.Getenv("SPDK_TLS_KEY_FILE"); v != "" {
+		cfg.TLS.KeyFile = v
+	}
+	if v := os.Getenv("SPDK_TLS_CA_FILE"); v != "" {
+		cfg.TLS.CAFile = v
+	}
+}
@@ -0,0 +1,75 @@
+package spdkclient
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// ResourceDetector implements resource.Detector by asking SPDK itself for
+// spdk.version, spdk.reactor.count, and the set of bdev product names/drivers in
+// use, so those show up as resource attributes alongside the host/process/container
+// detectors instead of only on individual metric points.
+type ResourceDetector struct {
+	client *Client
+}
+
+// NewResourceDetector returns a resource.Detector that queries client for SPDK's
+// version, reactor count, and bdev driver mix. It's meant to be passed to
+// resource.WithDetectors alongside the stdlib detectors.
+func NewResourceDetector(client *Client) *ResourceDetector {
+	return &ResourceDetector{client: client}
+}
+
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+// Detect gathers whatever SPDK attributes are reachable and returns them as a
+// schemaless resource. Individual RPC failures are swallowed rather than failing
+// the whole detector, since SPDK may not be reachable yet when the process starts.
+func (d *ResourceDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+
+	var ver versionResult
+	if err := d.client.Call(ctx, "spdk_get_version", nil, &ver); err == nil && ver.Version != "" {
+		attrs = append(attrs, attribute.String("spdk.version", ver.Version))
+	}
+
+	var reactors ReactorsResult
+	if err := d.client.Call(ctx, "framework_get_reactors", nil, &reactors); err == nil {
+		attrs = append(attrs, attribute.Int("spdk.reactor.count", len(reactors.Reactors)))
+	}
+
+	var bdevs []bdevInfo
+	if err := d.client.Call(ctx, "bdev_get_bdevs", nil, &bdevs); err == nil && len(bdevs) > 0 {
+		attrs = append(attrs,
+			attribute.StringSlice("spdk.bdev.product_name", distinct(bdevs, func(b bdevInfo) string { return b.ProductName })),
+			attribute.StringSlice("spdk.bdev.driver", distinct(bdevs, func(b bdevInfo) string { return b.driver() })),
+		)
+	}
+
+	return resource.NewSchemaless(attrs...), nil
+}
+
+// distinct returns the sorted, de-duplicated set of key(b) over bdevs, skipping
+// empty values.
+func distinct(bdevs []bdevInfo, key func(bdevInfo) string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, b := range bdevs {
+		v := key(b)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
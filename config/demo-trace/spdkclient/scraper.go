@@ -0,0 +1,113 @@
+package spdkclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MethodHandler performs one scrape attempt of a configured JSON-RPC method. It is
+// invoked once per attempt, so up to Retries+1 times per tick when attempts fail.
+type MethodHandler func(ctx context.Context) error
+
+// Scraper drives one goroutine per configured method, each on its own interval,
+// timeout and retry budget, so a slow or flaky method can't stall the others.
+type Scraper struct {
+	client   *Client
+	methods  []MethodConfig
+	handlers map[string]MethodHandler
+}
+
+// NewScraper builds a Scraper for cfg.Methods. bdevPoller is wired in as the handler
+// for bdev_get_iostat; other configured methods fall back to a generic handler that
+// just exercises the RPC call and discards the result, which is enough to keep
+// thread_get_stats/nvmf_get_subsystems/iobuf_get_stats scrapes alive until they grow
+// dedicated metrics of their own.
+func NewScraper(client *Client, cfg *Config, meter metric.Meter, bdevPoller *BdevPoller) (*Scraper, error) {
+	s := &Scraper{
+		client:  client,
+		methods: cfg.Methods,
+		handlers: map[string]MethodHandler{
+			"bdev_get_iostat": func(ctx context.Context) error {
+				_, err := bdevPoller.Poll(ctx)
+				return err
+			},
+		},
+	}
+	return s, nil
+}
+
+// SetHandler overrides (or adds) the handler used for method, letting callers wrap
+// the built-in bdev handling with their own tracing or metrics.
+func (s *Scraper) SetHandler(method string, h MethodHandler) {
+	s.handlers[method] = h
+}
+
+// genericHandler calls method and discards the result, for methods without a
+// dedicated metrics handler yet.
+func (s *Scraper) genericHandler(method string) MethodHandler {
+	return func(ctx context.Context) error {
+		return s.client.Call(ctx, method, nil, nil)
+	}
+}
+
+func (s *Scraper) handlerFor(cfg MethodConfig) MethodHandler {
+	if h, ok := s.handlers[cfg.Method]; ok {
+		return h
+	}
+	return s.genericHandler(cfg.Method)
+}
+
+// Run starts one ticker loop per configured method and blocks until ctx is done.
+// onResult, if non-nil, is called once per tick of a method, after its attempts are
+// done, with the method name and the outcome (nil once an attempt succeeds). A single failed scrape never aborts the loop or the
+// other methods' tickers.
+func (s *Scraper) Run(ctx context.Context, onResult func(method string, err error)) {
+	done := make(chan struct{})
+	for _, m := range s.methods {
+		go s.runMethod(ctx, m, onResult, done)
+	}
+	<-ctx.Done()
+	for range s.methods {
+		<-done
+	}
+}
+
+func (s *Scraper) runMethod(ctx context.Context, cfg MethodConfig, onResult func(method string, err error), done chan<- struct{}) {
+	defer func() { done <- struct{}{} }()
+
+	handler := s.handlerFor(cfg)
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.scrapeWithRetry(ctx, cfg, handler)
+			if onResult != nil {
+				onResult(cfg.Method, err)
+			}
+		}
+	}
+}
+
+func (s *Scraper) scrapeWithRetry(ctx context.Context, cfg MethodConfig, handler MethodHandler) error {
+	var lastErr error
+	attempts := cfg.Retries + 1
+	for i := 0; i < attempts; i++ {
+		scrapeCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		lastErr = handler(scrapeCtx)
+		cancel()
+		if lastErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return lastErr
+		}
+	}
+	return fmt.Errorf("spdkclient: %s failed after %d attempts: %w", cfg.Method, attempts, lastErr)
+}
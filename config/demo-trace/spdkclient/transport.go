@@ -0,0 +1,132 @@
+package spdkclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// transport sends one JSON-RPC 2.0 request and returns the decoded response envelope,
+// with its result still JSON-encoded.
+type transport interface {
+	call(ctx context.Context, req rpcRequest) (rpcResponse, error)
+}
+
+// newTransport picks an http(s) or Unix-domain-socket transport based on cfg.Addr's
+// scheme: "unix:///path/to.sock" dials the socket directly, "http://" or "https://"
+// posts to that URL, and any other scheme is rejected. This lets Client.Call, and in turn BdevPoller.Poll and Scraper,
+// work the same way whether SPDK exposes its RPC server over HTTP or, as most
+// production deployments do, a Unix socket.
+func newTransport(cfg *Config) (transport, error) {
+	switch {
+	case strings.HasPrefix(cfg.Addr, "unix://"):
+		return newUnixTransport(cfg.Addr)
+	case strings.HasPrefix(cfg.Addr, "http://"), strings.HasPrefix(cfg.Addr, "https://"):
+		return newHTTPTransport(cfg)
+	default:
+		return nil, fmt.Errorf("spdkclient: unsupported addr %q, want http(s):// or unix://", cfg.Addr)
+	}
+}
+
+// httpTransport posts JSON-RPC requests to an http(s) endpoint, optionally presenting
+// a client TLS certificate.
+type httpTransport struct {
+	addr   string
+	auth   AuthConfig
+	client *http.Client
+}
+
+func newHTTPTransport(cfg *Config) (*httpTransport, error) {
+	rt, err := httpRoundTripper(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTransport{
+		addr: cfg.Addr,
+		auth: cfg.Auth,
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(rt),
+		},
+	}, nil
+}
+
+func (t *httpTransport) call(ctx context.Context, req rpcRequest) (rpcResponse, error) {
+	var resp rpcResponse
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.addr, bytes.NewReader(body))
+	if err != nil {
+		return resp, fmt.Errorf("build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	t.setAuth(httpReq)
+
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, fmt.Errorf("read response: %w", err)
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return resp, fmt.Errorf("decode response: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) setAuth(req *http.Request) {
+	switch t.auth.Type {
+	case AuthBasic:
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+t.auth.Token)
+	}
+}
+
+func httpRoundTripper(tlsCfg TLSConfig) (http.RoundTripper, error) {
+	if tlsCfg.CertFile == "" && tlsCfg.KeyFile == "" && tlsCfg.CAFile == "" {
+		return http.DefaultTransport, nil
+	}
+
+	tc := &tls.Config{}
+
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("spdkclient: load client TLS cert: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+
+	if tlsCfg.CAFile != "" {
+		caPEM, err := os.ReadFile(tlsCfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("spdkclient: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("spdkclient: no certificates found in %q", tlsCfg.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tc
+	return base, nil
+}